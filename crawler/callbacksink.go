@@ -0,0 +1,33 @@
+package crawler
+
+import "net/http"
+
+// CallbackSink adapts plain functions to the Sink interface, for
+// programmatic embedding without writing a dedicated type. A nil field is
+// simply not called for that event.
+type CallbackSink struct {
+	OnResponseFunc func(url string, headers http.Header, body []byte, meta ResponseMeta)
+	OnErrorFunc    func(url string, err error)
+	OnLinkFunc     func(from, to string, tag LinkTag)
+}
+
+// OnResponse calls OnResponseFunc, if set.
+func (s *CallbackSink) OnResponse(url string, headers http.Header, body []byte, meta ResponseMeta) {
+	if s.OnResponseFunc != nil {
+		s.OnResponseFunc(url, headers, body, meta)
+	}
+}
+
+// OnError calls OnErrorFunc, if set.
+func (s *CallbackSink) OnError(url string, err error) {
+	if s.OnErrorFunc != nil {
+		s.OnErrorFunc(url, err)
+	}
+}
+
+// OnLink calls OnLinkFunc, if set.
+func (s *CallbackSink) OnLink(from, to string, tag LinkTag) {
+	if s.OnLinkFunc != nil {
+		s.OnLinkFunc(from, to, tag)
+	}
+}
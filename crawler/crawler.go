@@ -2,37 +2,85 @@
 package crawler
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"regexp"
-	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"golang.org/x/net/html"
+
+	"github.com/ciprian-test/web-crawler/warc"
 )
 
 var findMetaRefreshRegexp = regexp.MustCompile(`<meta\s+http-equiv=["']?refresh["']?\s+content=["']?[^;]+;\s*url=([^"']+)["']?`)
 var findLinkRegexp = regexp.MustCompile(`https?://[^\s"']+`)
 
+// defaultPerHostConcurrency caps how many requests we keep in flight against
+// a single host, independent of the crawler's global concurrency limit.
+const defaultPerHostConcurrency = 2
+
+// defaultUserAgent is sent on every request and used to select the matching
+// group in a site's robots.txt.
+const defaultUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36"
+
+// hostState tracks politeness bookkeeping for a single host: how many
+// requests are currently in flight and when the last one was let through.
+type hostState struct {
+	mutex     sync.Mutex
+	lastFetch time.Time
+	semaphore chan struct{}
+}
+
 // Crawler main structure for the crawler
 type Crawler struct {
-	allowedDomains  []string       // Only crawl links from these domains
-	semaphore       chan struct{}  // Limit concurrency
-	mutex           sync.Mutex     // Synchronize access to crawled links data
-	wait            sync.WaitGroup // Wait for all routines to finish
-	discoveredLinks map[string]map[string]string
+	allowedDomains []string       // Only crawl links from these domains
+	maxConcurrency int            // Number of crawl workers to run
+	queue          Queue          // Frontier of URLs to crawl plus their recorded outcome
+	pending        sync.WaitGroup // Outstanding queued-but-not-yet-done items
+
+	userAgent     string // Sent on every request and matched against robots.txt groups
+	crawlDelay    time.Duration
+	respectRobots bool
+	robotsCache   map[string]*robotsRules
+	robotsMutex   sync.Mutex
+
+	hostStates map[string]*hostState // Per-host in-flight cap and politeness delay
+	hostMutex  sync.Mutex
+
+	warcWriter  *warc.Writer
+	warcMaxSize int64
+
+	maxDepth int       // Hop limit for primary links; see SetMaxDepth
+	scope    ScopeFunc // Overrides the maxDepth policy; see SetScope
+
+	sinks []Sink // Receive crawl events as they happen; see AddSink
+
+	ifModifiedSince bool // Send If-Modified-Since using QueueItem.LastMod; see SetIfModifiedSince
 }
 
-// NewCrawler creates a new Crawler with a max concurrency limit to avoid damaging the crawler website(s)
+// NewCrawler creates a new Crawler with a max concurrency limit to avoid damaging the crawler website(s).
+// Its frontier is kept in memory; use NewCrawlerWithQueue for a persistent, restartable crawl.
 func NewCrawler(maxConcurrency int) *Crawler {
+	return NewCrawlerWithQueue(maxConcurrency, NewMemoryQueue())
+}
+
+// NewCrawlerWithQueue creates a new Crawler backed by queue, e.g. a
+// NewDiskQueue so the crawl's frontier and results survive a crash and can
+// later be picked back up with Resume.
+func NewCrawlerWithQueue(maxConcurrency int, queue Queue) *Crawler {
 	return &Crawler{
-		allowedDomains:  []string{},
-		discoveredLinks: make(map[string]map[string]string),
-		semaphore:       make(chan struct{}, maxConcurrency),
+		allowedDomains: []string{},
+		maxConcurrency: maxConcurrency,
+		queue:          queue,
+		userAgent:      defaultUserAgent,
+		crawlDelay:     defaultCrawlDelay,
+		robotsCache:    make(map[string]*robotsRules),
+		hostStates:     make(map[string]*hostState),
 	}
 }
 
@@ -41,114 +89,309 @@ func (c *Crawler) SetAllowedDomains(allowedDomains []string) {
 	c.allowedDomains = allowedDomains
 }
 
-// Crawl - Start crawling
-func (c *Crawler) Crawl(startURL string) {
-	c.wait.Add(1)
+// SetUserAgent overrides the User-Agent sent on every request, and the
+// group matched against robots.txt when SetRespectRobots is enabled.
+func (c *Crawler) SetUserAgent(userAgent string) {
+	c.userAgent = userAgent
+}
 
-	go c.crawlURL(startURL)
+// SetCrawlDelay sets the minimum time to wait between requests to the same
+// host when robots.txt doesn't specify its own Crawl-delay.
+func (c *Crawler) SetCrawlDelay(delay time.Duration) {
+	c.crawlDelay = delay
+}
 
-	c.wait.Wait()
-	close(c.semaphore) // Close the semaphore when done
+// SetRespectRobots enables or disables robots.txt compliance. When enabled,
+// disallowed URLs are skipped before being enqueued and Crawl-delay (if
+// present) overrides SetCrawlDelay on a per-host basis.
+func (c *Crawler) SetRespectRobots(respect bool) {
+	c.respectRobots = respect
 }
 
-// PrintLinks print to the discovered links
-func (c *Crawler) PrintLinks(includeDetails bool) {
-	links := make([]string, 0, len(c.discoveredLinks))
-	for link := range c.discoveredLinks {
-		links = append(links, link)
-	}
+// SetWARCMaxSize rotates the WARC output to a new file once the record
+// bytes written to the current one, counted before compression, would
+// exceed size. Call this before SetWARCOutput; it has no
+// effect afterwards. Zero (the default) disables rotation.
+func (c *Crawler) SetWARCMaxSize(size int64) {
+	c.warcMaxSize = size
+}
 
-	sort.Strings(links)
+// SetWARCOutput records every successfully fetched response as a WARC 1.0
+// response record (plus its matching request record) written to path. A
+// ".gz" extension on path enables gzip-per-record compression.
+func (c *Crawler) SetWARCOutput(path string) error {
+	writer, err := warc.NewWriter(path, warc.Options{
+		Gzip:     strings.HasSuffix(path, ".gz"),
+		MaxBytes: c.warcMaxSize,
+	})
+	if err != nil {
+		return err
+	}
 
-	for _, link := range links {
-		details := c.discoveredLinks[link]
+	c.warcWriter = writer
 
-		fmt.Println(link)
+	return nil
+}
 
-		if !includeDetails {
-			continue
+// Close releases the resources held by the crawler's queue and, if
+// configured, its WARC output file. Call it once Crawl or Resume returns.
+func (c *Crawler) Close() error {
+	if c.warcWriter != nil {
+		if err := c.warcWriter.Close(); err != nil {
+			return err
 		}
+	}
 
-		if val, ok := details["newLocation"]; ok {
-			fmt.Printf("\tRedirects to: %s\n", val)
+	for _, sink := range c.sinks {
+		if closer, ok := sink.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				return err
+			}
 		}
+	}
 
-		if val, ok := details["err"]; ok {
-			fmt.Printf("\tError detected: %s\n", val)
-		}
+	return c.queue.Close()
+}
+
+// hostStateFor returns the hostState for host, creating it on first use.
+func (c *Crawler) hostStateFor(host string) *hostState {
+	c.hostMutex.Lock()
+	defer c.hostMutex.Unlock()
+
+	state, ok := c.hostStates[host]
+	if !ok {
+		state = &hostState{semaphore: make(chan struct{}, defaultPerHostConcurrency)}
+		c.hostStates[host] = state
 	}
 
-	fmt.Printf("Found %d unique links", len(links))
+	return state
 }
 
-func (c *Crawler) crawlURL(link string) {
-	defer c.wait.Done()
+// awaitPoliteness blocks until it is this host's turn to be fetched,
+// respecting both the per-host in-flight cap and the crawl delay. It
+// returns a release function that must be called once the fetch completes.
+func (c *Crawler) awaitPoliteness(baseURL *url.URL) func() {
+	state := c.hostStateFor(baseURL.Host)
+
+	state.semaphore <- struct{}{}
+
+	// Hold the lock across the wait so that when more than one request is
+	// in flight (defaultPerHostConcurrency > 1), they queue up through this
+	// check one at a time instead of racing past it against the same stale
+	// lastFetch. lastFetch is stamped here, at acquisition, rather than in
+	// release() - otherwise a slot freed by a fetch that's still running
+	// would let the next goroutine through before lastFetch reflects it.
+	state.mutex.Lock()
+	if wait := c.crawlDelayFor(baseURL) - time.Since(state.lastFetch); wait > 0 {
+		time.Sleep(wait)
+	}
+	state.lastFetch = time.Now()
+	state.mutex.Unlock()
 
-	// Acquire a slot
-	c.semaphore <- struct{}{}
-	defer func() { <-c.semaphore }()
+	return func() {
+		<-state.semaphore
+	}
+}
+
+// Crawl - Start crawling from startURL
+func (c *Crawler) Crawl(startURL string) {
+	c.enqueue(startURL, 0)
+	c.discoverSitemaps(startURL)
+	c.runUntilDone()
+}
+
+// Resume continues a crawl whose frontier and results were persisted to a
+// BoltDB file at dbPath by a Crawler using a disk-backed Queue (see
+// NewDiskQueue), picking up wherever it was left off - after a crash or an
+// interrupted process - and running until the frontier is empty.
+func (c *Crawler) Resume(dbPath string) error {
+	queue, err := NewDiskQueue(dbPath)
+	if err != nil {
+		return err
+	}
+
+	c.queue = queue
 
-	c.mutex.Lock()
+	// Every URL still queued or in progress (Results() reports it as nil)
+	// is outstanding work runUntilDone must wait for, but it was never
+	// counted into c.pending by this Crawler - that happened in whatever
+	// process originally enqueued it. Without this, pending.Done() outruns
+	// pending.Add() the moment a surviving item is popped.
+	for _, result := range queue.Results() {
+		if result == nil {
+			c.pending.Add(1)
+		}
+	}
+
+	c.runUntilDone()
+
+	return nil
+}
+
+// enqueue pushes url onto the frontier at depth, tracking it as
+// outstanding work if it wasn't already known. A URL disallowed by
+// robots.txt (when SetRespectRobots is enabled) is not pushed.
+func (c *Crawler) enqueue(url string, depth int) {
+	c.enqueueWithLastMod(url, depth, time.Time{})
+}
 
-	if _, ok := c.discoveredLinks[link]; ok {
-		c.mutex.Unlock()
+// enqueueWithLastMod is enqueue plus a sitemap-reported last-modified time,
+// recorded on the QueueItem for SetIfModifiedSince to use once it's popped.
+func (c *Crawler) enqueueWithLastMod(link string, depth int, lastMod time.Time) {
+	if linkURL, err := url.Parse(link); err == nil && !c.isAllowedByRobots(linkURL) {
 		return
 	}
 
-	linkDetails := map[string]string{}
+	if c.queue.Push(link, depth, lastMod) {
+		c.pending.Add(1)
+	}
+}
+
+// runUntilDone starts maxConcurrency workers pulling from the frontier and
+// blocks until every queued item has been marked done.
+func (c *Crawler) runUntilDone() {
+	stop := make(chan struct{})
+
+	go func() {
+		c.pending.Wait()
+		close(stop)
+	}()
+
+	var workers sync.WaitGroup
+	for i := 0; i < c.maxConcurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			c.worker(stop)
+		}()
+	}
+	workers.Wait()
+}
+
+// worker repeatedly pops items off the frontier until stop is closed, i.e.
+// until there is no outstanding work left anywhere in the pool.
+func (c *Crawler) worker(stop <-chan struct{}) {
+	for {
+		item, ok := c.queue.Pop()
+		if !ok {
+			select {
+			case <-stop:
+				return
+			case <-time.After(10 * time.Millisecond):
+				continue
+			}
+		}
+
+		c.crawlItem(item)
+	}
+}
+
+func (c *Crawler) crawlItem(item QueueItem) {
+	defer c.pending.Done()
+
+	link := item.URL
 
 	baseURL, err := url.Parse(link)
 	if err != nil || !c.isDomainAllowed(baseURL.Host) {
-		c.mutex.Unlock()
 		return
 	}
 
-	c.discoveredLinks[link] = linkDetails
-	c.mutex.Unlock()
+	if !c.isAllowedByRobots(baseURL) {
+		return
+	}
+
+	release := c.awaitPoliteness(baseURL)
+	defer release()
+
+	ifModifiedSince := time.Time{}
+	if c.ifModifiedSince {
+		ifModifiedSince = item.LastMod
+	}
+
+	start := time.Now()
+	result, err := c.getURL(link, ifModifiedSince)
+	duration := time.Since(start)
 
-	body, contentType, location, err := c.getURL(link)
 	if err != nil {
-		linkDetails["err"] = err.Error()
+		c.queue.MarkDone(link, QueueResult{Status: "error", Error: err.Error()})
+		c.notifyError(link, err)
 		return
 	}
 
-	links := []string{}
+	if result.statusCode == http.StatusNotModified {
+		c.queue.MarkDone(link, QueueResult{Status: "not-modified"})
+		c.notifyResponse(link, result, "", item.Depth, duration)
+		return
+	}
 
-	linkDetails["contentType"] = contentType
-	if len(location) > 0 {
-		newLocationURL := resolveURL(location, baseURL)
-		linkDetails["newLocation"] = newLocationURL
+	contentType := result.headers.Get("Content-Type")
 
-		if c.isLocationAllowed(newLocationURL) {
-			links = append(links, newLocationURL)
-		}
-	} else {
-		linksDetails, err := c.extractLinks(baseURL, body, contentType)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
+	if len(result.location) > 0 {
+		newLocationURL := resolveURL(result.location, baseURL)
+		c.queue.MarkDone(link, QueueResult{Status: "done", ContentType: contentType, Redirect: newLocationURL})
 
-		c.mutex.Lock()
-		for link, needsCrawling := range linksDetails {
-			if needsCrawling {
-				links = append(links, link)
-			} else if c.isDomainAllowedForLink(link) {
-				c.discoveredLinks[link] = map[string]string{}
-			}
+		c.notifyLink(link, newLocationURL, TagPrimary)
+		c.notifyResponse(link, result, newLocationURL, item.Depth, duration)
+
+		c.followLink(newLocationURL, TagPrimary, item.Depth+1)
+		return
+	}
+
+	c.queue.MarkDone(link, QueueResult{Status: "done", ContentType: contentType})
+
+	linksDetails, err := c.extractLinks(baseURL, result.body, contentType)
+	if err != nil {
+		fmt.Println(err)
+		c.notifyResponse(link, result, "", item.Depth, duration)
+		return
+	}
+
+	for to, tag := range linksDetails {
+		c.notifyLink(link, to, tag)
+	}
+
+	c.notifyResponse(link, result, "", item.Depth, duration)
+
+	for to, tag := range linksDetails {
+		depth := item.Depth
+		if tag == TagPrimary {
+			depth++
 		}
-		c.mutex.Unlock()
 
-		links = uniqueStrings(links)
+		c.followLink(to, tag, depth)
 	}
+}
 
-	for _, newLink := range links {
-		c.wait.Add(1)
-		go c.crawlURL(newLink)
+// followLink enqueues link for crawling at depth, unless it's outside the
+// allowed domains. A link that's out of scope (see SetMaxDepth and
+// SetScope) is recorded via Queue.Seen instead, so it's remembered without
+// ever being fetched.
+func (c *Crawler) followLink(link string, tag LinkTag, depth int) {
+	if !c.isDomainAllowedForLink(link) {
+		return
+	}
+
+	if !c.inScope(link, tag, depth) {
+		c.queue.Seen(link)
+		return
 	}
+
+	c.enqueue(link, depth)
+}
+
+// fetchResult holds everything retrieved from a single HTTP fetch: its
+// body, response headers, status code, and redirect target (if any).
+type fetchResult struct {
+	body       string
+	headers    http.Header
+	statusCode int
+	location   string
 }
 
-func (c *Crawler) getURL(link string) (string, string, string, error) {
+// getURL fetches link. If ifModifiedSince is non-zero, it is sent as an
+// If-Modified-Since header, and a 304 response is returned as a
+// fetchResult with no error (see SetIfModifiedSince).
+func (c *Crawler) getURL(link string, ifModifiedSince time.Time) (fetchResult, error) {
 	// Create a custom HTTP client that does not follow redirects
 	client := &http.Client{
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
@@ -159,36 +402,70 @@ func (c *Crawler) getURL(link string) (string, string, string, error) {
 	}
 
 	req, err := http.NewRequest("GET", link, nil)
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36")
+	req.Header.Set("User-Agent", c.userAgent)
+	if !ifModifiedSince.IsZero() {
+		req.Header.Set("If-Modified-Since", ifModifiedSince.UTC().Format(http.TimeFormat))
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", "", "", err
+		return fetchResult{}, err
 	}
 	defer resp.Body.Close()
 
-	contentType := resp.Header.Get("Content-Type")
+	if resp.StatusCode == http.StatusNotModified {
+		return fetchResult{headers: resp.Header, statusCode: resp.StatusCode}, nil
+	}
 
 	if resp.StatusCode == http.StatusMovedPermanently || resp.StatusCode == http.StatusFound || resp.StatusCode == http.StatusPermanentRedirect {
 		location := resp.Header.Get("Location")
-		return "", contentType, location, nil
+		c.recordWARC(req, resp)
+		return fetchResult{headers: resp.Header, statusCode: resp.StatusCode, location: location}, nil
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", "", "", fmt.Errorf("%d status code", resp.StatusCode)
+		return fetchResult{}, fmt.Errorf("%d status code", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		fmt.Println("Error reading body:", err)
-		return "", "", "", fmt.Errorf("Error reading URL body (%s)", err)
+		return fetchResult{}, fmt.Errorf("Error reading URL body (%s)", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	c.recordWARC(req, resp)
+
+	return fetchResult{body: string(body), headers: resp.Header, statusCode: resp.StatusCode}, nil
+}
+
+// recordWARC appends resp, along with the request that produced it, to the
+// configured WARC output. It is a no-op unless SetWARCOutput has been
+// called. resp.Body must still be readable (not yet drained and closed).
+func (c *Crawler) recordWARC(req *http.Request, resp *http.Response) {
+	if c.warcWriter == nil {
+		return
 	}
 
-	return string(body), contentType, "", nil
+	var requestHead bytes.Buffer
+	if err := req.Write(&requestHead); err != nil {
+		fmt.Println("Error serializing request for WARC output:", err)
+		return
+	}
+
+	var responseRaw bytes.Buffer
+	if err := resp.Write(&responseRaw); err != nil {
+		fmt.Println("Error serializing response for WARC output:", err)
+		return
+	}
+
+	if err := c.warcWriter.WriteResponse(req.URL.String(), requestHead.Bytes(), responseRaw.Bytes()); err != nil {
+		fmt.Println("Error writing WARC record:", err)
+	}
 }
 
 // Extract links from the URL body
-func (c *Crawler) extractLinks(baseURL *url.URL, body string, contentType string) (map[string]bool, error) {
+func (c *Crawler) extractLinks(baseURL *url.URL, body string, contentType string) (map[string]LinkTag, error) {
 	if strings.Contains(contentType, "/javascript") || strings.Contains(contentType, "/css") {
 		// Look for links in certain file types
 		return extractLinksFromFile(body, baseURL), nil
@@ -199,42 +476,45 @@ func (c *Crawler) extractLinks(baseURL *url.URL, body string, contentType string
 		return nil, err
 	}
 
-	// Extract from meta refresh
+	// Extract from meta refresh; it's a navigation, like following <a href>
 	if metaRefresh := extractMetaRefresh(body); metaRefresh != "" {
-		linksDetails[resolveURL(metaRefresh, baseURL)] = true
+		linksDetails[resolveURL(metaRefresh, baseURL)] = TagPrimary
 	}
 
 	return linksDetails, nil
 }
 
-// Extract links from the URL body
-func (c *Crawler) extractLinksFromHTML(baseURL *url.URL, body string) (map[string]bool, error) {
+// Extract links from the URL body, tagging navigations (<a>, <iframe>, and
+// meta refresh) as TagPrimary and embedded resources needed for a complete
+// page snapshot (<img>, <link>, <script>, <source>, <embed>, <object>,
+// form actions) as TagRelated.
+func (c *Crawler) extractLinksFromHTML(baseURL *url.URL, body string) (map[string]LinkTag, error) {
 	doc, err := html.Parse(strings.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("Error parsing HTML body (%s)", err)
 	}
 
-	newLinks := map[string]bool{}
+	newLinks := map[string]LinkTag{}
 
 	var traverse func(*html.Node)
 	traverse = func(n *html.Node) {
 		if n.Type == html.ElementNode {
 			switch n.Data {
-			case "img":
-				val := getAttributeValue(n, []string{"src"})
+			case "a", "iframe":
+				val := getAttributeValue(n, []string{"src", "href"})
 				if len(val) > 0 {
-					newLinks[resolveURL(val, baseURL)] = false
+					newLinks[resolveURL(val, baseURL)] = TagPrimary
 				}
 
-			case "a", "link", "iframe", "embed", "object", "source", "script":
+			case "img", "link", "script", "source", "embed", "object":
 				val := getAttributeValue(n, []string{"src", "href"})
 				if len(val) > 0 {
-					newLinks[resolveURL(val, baseURL)] = true
+					newLinks[resolveURL(val, baseURL)] = TagRelated
 				}
 			case "form":
 				val := getAttributeValue(n, []string{"action"})
 				if len(val) > 0 {
-					newLinks[resolveURL(val, baseURL)] = false
+					newLinks[resolveURL(val, baseURL)] = TagRelated
 				}
 			}
 		}
@@ -266,15 +546,6 @@ func (c *Crawler) isDomainAllowedForLink(link string) bool {
 	return c.isDomainAllowed(linkURL.Host)
 }
 
-func (c *Crawler) isLocationAllowed(location string) bool {
-	locationURL, err := url.Parse(location)
-	if err != nil {
-		return false
-	}
-
-	return c.isDomainAllowed(locationURL.Host)
-}
-
 func resolveURL(link string, baseURL *url.URL) string {
 	parsed, err := baseURL.Parse(link)
 	if err != nil {
@@ -286,20 +557,6 @@ func resolveURL(link string, baseURL *url.URL) string {
 	return parsed.String()
 }
 
-func uniqueStrings(input []string) []string {
-	seen := make(map[string]bool)
-	unique := []string{}
-
-	for _, str := range input {
-		if _, exists := seen[str]; !exists {
-			seen[str] = true
-			unique = append(unique, str)
-		}
-	}
-
-	return unique
-}
-
 func getAttributeValue(n *html.Node, keys []string) string {
 	for _, attr := range n.Attr {
 		for _, key := range keys {
@@ -321,13 +578,17 @@ func extractMetaRefresh(body string) string {
 	return ""
 }
 
-func extractLinksFromFile(body string, baseURL *url.URL) map[string]bool {
+// extractLinksFromFile pulls absolute http(s) URLs (e.g. in CSS url(...)
+// references) out of a non-HTML file such as a stylesheet or script;
+// relative references are not found. They're all tagged TagRelated:
+// resources a page needs, not navigations.
+func extractLinksFromFile(body string, baseURL *url.URL) map[string]LinkTag {
 	matches := findLinkRegexp.FindAllString(body, -1)
 
-	newLinks := map[string]bool{}
+	newLinks := map[string]LinkTag{}
 
 	for _, match := range matches {
-		newLinks[resolveURL(match, baseURL)] = true
+		newLinks[resolveURL(match, baseURL)] = TagRelated
 	}
 
 	return newLinks
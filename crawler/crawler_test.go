@@ -10,6 +10,7 @@ func TestCrawl(t *testing.T) {
 	defer server.Close()
 
 	c := NewCrawler(5)
+	c.SetAllowedDomains([]string{mustHost(server.URL)})
 
 	// Start crawling from the root
 	c.Crawl(server.URL + "/")
@@ -23,8 +24,9 @@ func TestCrawl(t *testing.T) {
 		server.URL + "/image.jpg",
 	}
 
+	results := c.queue.Results()
 	for _, link := range expectedLinks {
-		if _, found := c.discoveredLinks[link]; !found {
+		if _, found := results[link]; !found {
 			t.Errorf("Expected link not found: %s", link)
 		}
 	}
@@ -35,11 +37,12 @@ func TestRedirectHandling(t *testing.T) {
 	defer server.Close()
 
 	c := NewCrawler(5)
+	c.SetAllowedDomains([]string{mustHost(server.URL)})
 
 	c.Crawl(server.URL + "/redirect")
 
 	// Ensure redirect was followed
-	if _, found := c.discoveredLinks[server.URL+"/"]; !found {
+	if _, found := c.queue.Results()[server.URL+"/"]; !found {
 		t.Errorf("Redirected link not followed: %s", server.URL+"/")
 	}
 }
@@ -49,12 +52,13 @@ func TestErrorHandling(t *testing.T) {
 	defer server.Close()
 
 	c := NewCrawler(5)
+	c.SetAllowedDomains([]string{mustHost(server.URL)})
 
 	c.Crawl(server.URL + "/error")
 
 	// Ensure error was recorded
-	if details, found := c.discoveredLinks[server.URL+"/error"]; found {
-		if _, errRecorded := details["err"]; !errRecorded {
+	if result, found := c.queue.Results()[server.URL+"/error"]; found {
+		if result == nil || result.Error == "" {
 			t.Errorf("Expected error not recorded for %s", server.URL+"/error")
 		}
 	} else {
@@ -80,14 +84,14 @@ func TestExtractLinks(t *testing.T) {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	expectedLinks := map[string]bool{
-		"http://example.com/page1":  true,
-		"http://external.com/page2": true,
+	expectedLinks := map[string]LinkTag{
+		"http://example.com/page1":  TagPrimary,
+		"http://external.com/page2": TagPrimary,
 	}
 
-	for link, needsCrawling := range expectedLinks {
-		if v, found := links[link]; !found || v != needsCrawling {
-			t.Errorf("Expected link %s with crawling: %v not found or mismatched", link, needsCrawling)
+	for link, tag := range expectedLinks {
+		if v, found := links[link]; !found || v != tag {
+			t.Errorf("Expected link %s with tag: %v not found or mismatched", link, tag)
 		}
 	}
 }
@@ -0,0 +1,219 @@
+package crawler
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	frontierBucket = []byte("frontier")
+	resultsBucket  = []byte("results")
+	inflightBucket = []byte("inflight")
+)
+
+// statusPending marks a result record whose URL has been pushed (and
+// possibly popped) but not yet reported via MarkDone, so Results() can tell
+// an item still queued or in progress apart from one that was only ever Seen.
+const statusPending = "pending"
+
+// diskQueue is a Queue backed by a BoltDB file, so a crawl's frontier and
+// results survive a crash or an interrupted process. See Crawler.Resume.
+type diskQueue struct {
+	db *bolt.DB
+}
+
+// NewDiskQueue opens (creating if necessary) a persistent Queue at path.
+// Reopening a path from a previous crawl resumes with its frontier intact,
+// including any URL that was popped but never reached MarkDone - e.g.
+// because the previous process crashed mid-fetch - which is put back on
+// the frontier so it gets crawled again.
+func NewDiskQueue(path string) (Queue, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(frontierBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(resultsBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(inflightBucket); err != nil {
+			return err
+		}
+
+		return reconcileInFlight(tx)
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &diskQueue{db: db}, nil
+}
+
+// reconcileInFlight moves every URL still recorded in inflightBucket back
+// onto the frontier. An in-flight entry only survives to the next open if
+// the process died between Pop and MarkDone, so without this a crashed
+// fetch's URL would stay statusPending in resultsBucket forever, with no
+// Pop ever able to produce the MarkDone that would clear it.
+func reconcileInFlight(tx *bolt.Tx) error {
+	inflight := tx.Bucket(inflightBucket)
+	frontier := tx.Bucket(frontierBucket)
+
+	var keys [][]byte
+	err := inflight.ForEach(func(key, data []byte) error {
+		if err := frontier.Put(append([]byte(nil), key...), append([]byte(nil), data...)); err != nil {
+			return err
+		}
+		keys = append(keys, append([]byte(nil), key...))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := inflight.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (q *diskQueue) Push(url string, depth int, lastMod time.Time) bool {
+	var added bool
+
+	_ = q.db.Update(func(tx *bolt.Tx) error {
+		results := tx.Bucket(resultsBucket)
+		if results.Get([]byte(url)) != nil {
+			return nil
+		}
+
+		item, err := json.Marshal(QueueItem{URL: url, Depth: depth, LastMod: lastMod})
+		if err != nil {
+			return err
+		}
+
+		// Record url as pending in the same transaction that queues it, so a
+		// second Push before it's ever Pop()-ed still sees it as known.
+		pending, err := json.Marshal(QueueResult{Status: statusPending})
+		if err != nil {
+			return err
+		}
+		if err := results.Put([]byte(url), pending); err != nil {
+			return err
+		}
+
+		added = true
+		return tx.Bucket(frontierBucket).Put([]byte(url), item)
+	})
+
+	return added
+}
+
+func (q *diskQueue) Pop() (QueueItem, bool) {
+	var item QueueItem
+	var found bool
+
+	_ = q.db.Update(func(tx *bolt.Tx) error {
+		frontier := tx.Bucket(frontierBucket)
+
+		key, data := frontier.Cursor().First()
+		if key == nil {
+			return nil
+		}
+
+		if err := json.Unmarshal(data, &item); err != nil {
+			return err
+		}
+
+		// Keep the popped item in inflightBucket until MarkDone, so a crash
+		// before then leaves something for NewDiskQueue to put back on the
+		// frontier - see reconcileInFlight.
+		if err := tx.Bucket(inflightBucket).Put(key, data); err != nil {
+			return err
+		}
+
+		pending, err := json.Marshal(QueueResult{Status: statusPending})
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(resultsBucket).Put(key, pending); err != nil {
+			return err
+		}
+
+		found = true
+		return frontier.Delete(key)
+	})
+
+	return item, found
+}
+
+func (q *diskQueue) MarkDone(url string, result QueueResult) {
+	_ = q.db.Update(func(tx *bolt.Tx) error {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(inflightBucket).Delete([]byte(url)); err != nil {
+			return err
+		}
+		return tx.Bucket(resultsBucket).Put([]byte(url), data)
+	})
+}
+
+func (q *diskQueue) Seen(url string) bool {
+	var already bool
+
+	_ = q.db.Update(func(tx *bolt.Tx) error {
+		results := tx.Bucket(resultsBucket)
+		if results.Get([]byte(url)) != nil {
+			already = true
+			return nil
+		}
+
+		data, err := json.Marshal(QueueResult{})
+		if err != nil {
+			return err
+		}
+
+		return results.Put([]byte(url), data)
+	})
+
+	return already
+}
+
+func (q *diskQueue) Results() map[string]*QueueResult {
+	out := make(map[string]*QueueResult)
+
+	_ = q.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(resultsBucket).ForEach(func(key, data []byte) error {
+			var result QueueResult
+			if err := json.Unmarshal(data, &result); err != nil {
+				return err
+			}
+
+			if result.Status == statusPending {
+				out[string(key)] = nil
+			} else {
+				r := result
+				out[string(key)] = &r
+			}
+
+			return nil
+		})
+	})
+
+	return out
+}
+
+func (q *diskQueue) Close() error {
+	return q.db.Close()
+}
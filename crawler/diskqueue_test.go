@@ -0,0 +1,170 @@
+package crawler
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDiskQueuePushPopMarkDone(t *testing.T) {
+	q, err := NewDiskQueue(filepath.Join(t.TempDir(), "queue.db"))
+	if err != nil {
+		t.Fatalf("NewDiskQueue: %v", err)
+	}
+	defer q.Close()
+
+	if !q.Push("http://example.com/", 0, time.Time{}) {
+		t.Fatalf("expected first push to succeed")
+	}
+	if q.Push("http://example.com/", 0, time.Time{}) {
+		t.Errorf("expected duplicate push to be rejected")
+	}
+
+	item, ok := q.Pop()
+	if !ok {
+		t.Fatalf("expected an item to pop")
+	}
+	if item.URL != "http://example.com/" || item.Depth != 0 {
+		t.Errorf("unexpected item: %+v", item)
+	}
+
+	if _, ok := q.Pop(); ok {
+		t.Errorf("expected frontier to be empty")
+	}
+
+	q.MarkDone(item.URL, QueueResult{Status: "done", ContentType: "text/html"})
+
+	results := q.Results()
+	result, ok := results[item.URL]
+	if !ok || result == nil {
+		t.Fatalf("expected a recorded result for %s", item.URL)
+	}
+	if result.Status != "done" {
+		t.Errorf("expected status 'done', got %q", result.Status)
+	}
+}
+
+// TestDiskQueueRejectsDuplicatePushBeforePop guards against a regression
+// where Push only deduped against resultsBucket, which Push itself never
+// populated - a URL pushed twice before being popped (e.g. an <img> shared
+// by two pages) was added to the frontier twice, double-counting
+// Crawler.pending and hanging runUntilDone forever.
+func TestDiskQueueRejectsDuplicatePushBeforePop(t *testing.T) {
+	q, err := NewDiskQueue(filepath.Join(t.TempDir(), "queue.db"))
+	if err != nil {
+		t.Fatalf("NewDiskQueue: %v", err)
+	}
+	defer q.Close()
+
+	const n = 20
+	var wg sync.WaitGroup
+	added := make([]bool, n)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			added[i] = q.Push("http://example.com/shared.jpg", 0, time.Time{})
+		}(i)
+	}
+	wg.Wait()
+
+	var count int
+	for _, a := range added {
+		if a {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("expected exactly 1 of %d concurrent pushes to succeed, got %d", n, count)
+	}
+}
+
+// TestCrawlerResumeAfterSimulatedCrash confirms a URL that was pushed onto a
+// disk-backed frontier but never popped - as if the process died right
+// after seeding - is still crawled once Resume reopens the same file.
+func TestCrawlerResumeAfterSimulatedCrash(t *testing.T) {
+	server := mockServer()
+	defer server.Close()
+
+	dbPath := filepath.Join(t.TempDir(), "crawl.db")
+
+	queue, err := NewDiskQueue(dbPath)
+	if err != nil {
+		t.Fatalf("NewDiskQueue: %v", err)
+	}
+
+	crashed := NewCrawlerWithQueue(1, queue)
+	crashed.SetAllowedDomains([]string{mustHost(server.URL)})
+	crashed.enqueue(server.URL+"/", 0)
+
+	if err := queue.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	resumed := NewCrawler(2)
+	resumed.SetAllowedDomains([]string{mustHost(server.URL)})
+	resumed.SetCrawlDelay(0)
+
+	if err := resumed.Resume(dbPath); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	defer resumed.Close()
+
+	result, ok := resumed.queue.Results()[server.URL+"/"]
+	if !ok || result == nil || result.Status != "done" {
+		t.Errorf("expected seed URL to be crawled after resume, got %+v", result)
+	}
+}
+
+// TestCrawlerResumeRecoversURLPoppedButNeverMarkedDone covers the more
+// realistic crash: the process dies mid-fetch, after Pop removed the URL
+// from the frontier but before MarkDone ever ran. Resume must put it back
+// on the frontier, or pending.Wait() blocks forever waiting for a Pop that
+// can no longer happen.
+func TestCrawlerResumeRecoversURLPoppedButNeverMarkedDone(t *testing.T) {
+	server := mockServer()
+	defer server.Close()
+
+	dbPath := filepath.Join(t.TempDir(), "crawl.db")
+
+	queue, err := NewDiskQueue(dbPath)
+	if err != nil {
+		t.Fatalf("NewDiskQueue: %v", err)
+	}
+
+	if !queue.Push(server.URL+"/", 0, time.Time{}) {
+		t.Fatalf("expected push to succeed")
+	}
+	if _, ok := queue.Pop(); !ok {
+		t.Fatalf("expected an item to pop")
+	}
+	// Simulate a crash: close the DB with the item still in-flight, never
+	// having called MarkDone.
+	if err := queue.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	resumed := NewCrawler(2)
+	resumed.SetAllowedDomains([]string{mustHost(server.URL)})
+	resumed.SetCrawlDelay(0)
+
+	done := make(chan error, 1)
+	go func() { done <- resumed.Resume(dbPath) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Resume: %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Resume never returned: a URL popped but never MarkDone'd was never recrawled")
+	}
+	defer resumed.Close()
+
+	result, ok := resumed.queue.Results()[server.URL+"/"]
+	if !ok || result == nil || result.Status != "done" {
+		t.Errorf("expected the crashed URL to be recrawled after resume, got %+v", result)
+	}
+}
@@ -0,0 +1,99 @@
+package crawler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// jsonlRecord is one line of JSONLSink output: everything known about a
+// single crawled URL.
+type jsonlRecord struct {
+	URL         string   `json:"url"`
+	StatusCode  int      `json:"status_code,omitempty"`
+	ContentType string   `json:"content_type,omitempty"`
+	Redirect    string   `json:"redirect,omitempty"`
+	Error       string   `json:"error,omitempty"`
+	Links       []string `json:"links,omitempty"`
+	DurationMS  int64    `json:"duration_ms"`
+}
+
+// JSONLSink writes one JSON object per crawled URL, newline-delimited, to
+// an underlying writer. It's safe for concurrent use.
+type JSONLSink struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+	file    *os.File // set only by NewJSONLFileSink, for Close
+	links   map[string][]string
+}
+
+// NewJSONLSink streams records to w as they're produced.
+func NewJSONLSink(w io.Writer) *JSONLSink {
+	return &JSONLSink{encoder: json.NewEncoder(w), links: make(map[string][]string)}
+}
+
+// NewJSONLFileSink creates (or truncates) path and streams records to it.
+// Call Close, or Crawler.Close, once the crawl is done to flush and
+// release the file.
+func NewJSONLFileSink(path string) (*JSONLSink, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := NewJSONLSink(file)
+	sink.file = file
+
+	return sink, nil
+}
+
+// OnLink buffers to under from, so it can be included in from's record
+// once OnResponse reports from as done.
+func (s *JSONLSink) OnLink(from, to string, tag LinkTag) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.links[from] = append(s.links[from], to)
+}
+
+// OnResponse writes url's record, including every link buffered for it by
+// OnLink.
+func (s *JSONLSink) OnResponse(url string, headers http.Header, body []byte, meta ResponseMeta) {
+	s.mutex.Lock()
+	links := s.links[url]
+	delete(s.links, url)
+	s.mutex.Unlock()
+
+	s.write(jsonlRecord{
+		URL:         url,
+		StatusCode:  meta.StatusCode,
+		ContentType: meta.ContentType,
+		Redirect:    meta.Redirect,
+		Links:       links,
+		DurationMS:  meta.Duration.Milliseconds(),
+	})
+}
+
+// OnError writes a record carrying the fetch error instead of a response.
+func (s *JSONLSink) OnError(url string, err error) {
+	s.write(jsonlRecord{URL: url, Error: err.Error()})
+}
+
+func (s *JSONLSink) write(record jsonlRecord) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_ = s.encoder.Encode(record)
+}
+
+// Close releases the file opened by NewJSONLFileSink. It's a no-op for
+// sinks created with NewJSONLSink.
+func (s *JSONLSink) Close() error {
+	if s.file == nil {
+		return nil
+	}
+
+	return s.file.Close()
+}
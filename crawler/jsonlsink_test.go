@@ -0,0 +1,54 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJSONLSinkIncludesLinksDiscoveredBeforeResponse(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONLSink(&buf)
+
+	sink.OnLink("http://example.com/", "http://example.com/page1", TagPrimary)
+	sink.OnLink("http://example.com/", "http://example.com/img.png", TagRelated)
+	sink.OnResponse("http://example.com/", nil, []byte("<html></html>"), ResponseMeta{
+		StatusCode:  200,
+		ContentType: "text/html",
+		Duration:    5 * time.Millisecond,
+	})
+
+	var record jsonlRecord
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("failed to unmarshal record: %v", err)
+	}
+
+	if record.URL != "http://example.com/" {
+		t.Errorf("unexpected URL: %s", record.URL)
+	}
+	if len(record.Links) != 2 {
+		t.Errorf("expected 2 links, got %d: %v", len(record.Links), record.Links)
+	}
+	if record.DurationMS != 5 {
+		t.Errorf("expected duration_ms 5, got %d", record.DurationMS)
+	}
+}
+
+func TestJSONLSinkOnError(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONLSink(&buf)
+
+	fetchErr := errors.New("404 status code")
+	sink.OnError("http://example.com/missing", fetchErr)
+
+	var record jsonlRecord
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("failed to unmarshal record: %v", err)
+	}
+
+	if record.Error != fetchErr.Error() {
+		t.Errorf("expected error %q, got %q", fetchErr.Error(), record.Error)
+	}
+}
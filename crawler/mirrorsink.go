@@ -0,0 +1,142 @@
+package crawler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// MirrorSink writes each successfully fetched body to disk at
+// outDir/<host>/<path>, mirroring the crawled site's directory structure -
+// the approach fullscrape uses to produce a browsable offline copy.
+type MirrorSink struct {
+	outDir       string
+	rewriteLinks bool
+}
+
+// NewMirrorSink creates a MirrorSink rooted at outDir (created on first
+// write). When rewriteLinks is true, HTML href/src/action attributes
+// pointing at the same host are rewritten to relative filesystem paths, so
+// the mirror can be browsed offline without a server.
+func NewMirrorSink(outDir string, rewriteLinks bool) *MirrorSink {
+	return &MirrorSink{outDir: outDir, rewriteLinks: rewriteLinks}
+}
+
+// OnResponse writes body to its mirrored path under outDir.
+func (s *MirrorSink) OnResponse(rawURL string, headers http.Header, body []byte, meta ResponseMeta) {
+	relPath, err := urlToMirrorPath(rawURL)
+	if err != nil {
+		fmt.Println("MirrorSink: skipping", rawURL, err)
+		return
+	}
+
+	if s.rewriteLinks && strings.Contains(meta.ContentType, "text/html") {
+		body = rewriteHTMLLinks(body, rawURL)
+	}
+
+	target := filepath.Join(s.outDir, relPath)
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		fmt.Println("MirrorSink: creating directory for", rawURL, err)
+		return
+	}
+
+	if err := os.WriteFile(target, body, 0644); err != nil {
+		fmt.Println("MirrorSink: writing", rawURL, err)
+	}
+}
+
+// OnError is a no-op; MirrorSink only mirrors successful fetches.
+func (s *MirrorSink) OnError(url string, err error) {}
+
+// OnLink is a no-op; MirrorSink only mirrors successful fetches.
+func (s *MirrorSink) OnLink(from, to string, tag LinkTag) {}
+
+// urlToMirrorPath maps rawURL onto a relative filesystem path (host/path),
+// giving directory paths (including the root path) an index.html filename
+// and other extension-less paths a ".html" extension, so a mirror can be
+// served or browsed like a static site.
+func urlToMirrorPath(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	urlPath := parsed.Path
+	if urlPath == "" || strings.HasSuffix(urlPath, "/") {
+		urlPath = path.Join(urlPath, "index.html")
+	} else if path.Ext(urlPath) == "" {
+		urlPath += ".html"
+	}
+
+	return filepath.Join(parsed.Host, filepath.FromSlash(urlPath)), nil
+}
+
+// rewriteHTMLLinks rewrites href/src/action attributes that point at
+// rawURL's own host into paths relative to rawURL's mirrored file, leaving
+// cross-site links untouched. Best-effort: a parse failure leaves body
+// unmodified, and a link that can't be resolved is left as it was.
+func rewriteHTMLLinks(body []byte, rawURL string) []byte {
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return body
+	}
+
+	basePath, err := urlToMirrorPath(rawURL)
+	if err != nil {
+		return body
+	}
+
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return body
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for i, attr := range n.Attr {
+				if attr.Key != "href" && attr.Key != "src" && attr.Key != "action" {
+					continue
+				}
+
+				resolved := resolveURL(attr.Val, base)
+
+				linkURL, err := url.Parse(resolved)
+				if err != nil || linkURL.Host != base.Host {
+					continue
+				}
+
+				targetPath, err := urlToMirrorPath(resolved)
+				if err != nil {
+					continue
+				}
+
+				rel, err := filepath.Rel(filepath.Dir(basePath), targetPath)
+				if err != nil {
+					continue
+				}
+
+				n.Attr[i].Val = filepath.ToSlash(rel)
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return body
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,43 @@
+package crawler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMirrorSinkWritesBodyUnderHostAndPath(t *testing.T) {
+	dir := t.TempDir()
+	sink := NewMirrorSink(dir, false)
+
+	sink.OnResponse("http://example.com/blog/post", nil, []byte("hello"), ResponseMeta{ContentType: "text/html"})
+
+	data, err := os.ReadFile(filepath.Join(dir, "example.com", "blog", "post.html"))
+	if err != nil {
+		t.Fatalf("expected mirrored file, got error: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("expected body %q, got %q", "hello", data)
+	}
+}
+
+func TestMirrorSinkRewritesIntraSiteLinks(t *testing.T) {
+	dir := t.TempDir()
+	sink := NewMirrorSink(dir, true)
+
+	body := `<html><body><a href="/other">Other</a><a href="http://external.com/">External</a></body></html>`
+	sink.OnResponse("http://example.com/", nil, []byte(body), ResponseMeta{ContentType: "text/html"})
+
+	data, err := os.ReadFile(filepath.Join(dir, "example.com", "index.html"))
+	if err != nil {
+		t.Fatalf("expected mirrored file, got error: %v", err)
+	}
+
+	if !strings.Contains(string(data), `href="other.html"`) {
+		t.Errorf("expected intra-site link rewritten to a relative path, got: %s", data)
+	}
+	if !strings.Contains(string(data), `href="http://external.com/"`) {
+		t.Errorf("expected cross-site link left untouched, got: %s", data)
+	}
+}
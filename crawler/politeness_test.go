@@ -0,0 +1,63 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestAwaitPolitenessSerializesDelayAcrossConcurrentRequests guards against
+// a regression where two requests to the same host could both pass the
+// crawl-delay check against the same stale lastFetch, because lastFetch was
+// only stamped once a fetch completed rather than when it started.
+func TestAwaitPolitenessSerializesDelayAcrossConcurrentRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	c := NewCrawler(1)
+	baseURL := mustParseURL(server.URL)
+	c.SetCrawlDelay(100 * time.Millisecond)
+
+	const n = defaultPerHostConcurrency + 2
+
+	var mutex sync.Mutex
+	var starts []time.Time
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			release := c.awaitPoliteness(baseURL)
+			defer release()
+
+			mutex.Lock()
+			starts = append(starts, time.Now())
+			mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(starts) != n {
+		t.Fatalf("expected %d starts recorded, got %d", n, len(starts))
+	}
+
+	for i := 1; i < len(starts); i++ {
+		if gap := starts[i].Sub(starts[i-1]); gap < c.crawlDelay {
+			t.Errorf("requests %d and %d started only %s apart, want at least %s", i-1, i, gap, c.crawlDelay)
+		}
+	}
+}
+
+func mustParseURL(rawURL string) *url.URL {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		panic(err)
+	}
+
+	return parsed
+}
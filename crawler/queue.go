@@ -0,0 +1,127 @@
+package crawler
+
+import (
+	"sync"
+	"time"
+)
+
+// QueueItem is a URL frontier entry awaiting a crawl.
+type QueueItem struct {
+	URL     string
+	Depth   int
+	LastMod time.Time // sitemap-reported last-modified time, if any; see SetIfModifiedSince
+}
+
+// QueueResult is the recorded outcome of crawling a URL.
+type QueueResult struct {
+	Status      string // "done", "error" or "not-modified"; empty for a URL only recorded via Seen
+	Error       string
+	ContentType string
+	Redirect    string
+}
+
+// Queue is the crawl frontier: URLs waiting to be crawled plus the outcome
+// of URLs already crawled or merely referenced. Implementations must be
+// safe for concurrent use and must treat a URL pushed more than once as a
+// single entry.
+type Queue interface {
+	// Push enqueues url at depth for crawling, unless it has already been
+	// seen (queued, in progress, done, or just recorded via Seen). lastMod
+	// records the sitemap-reported last-modified time for url, if any (the
+	// zero Time if unknown). It reports whether url was newly added.
+	Push(url string, depth int, lastMod time.Time) bool
+	// Pop removes and returns the next item to crawl. ok is false if the
+	// frontier is currently empty.
+	Pop() (item QueueItem, ok bool)
+	// MarkDone records the outcome of crawling url.
+	MarkDone(url string, result QueueResult)
+	// Seen records url as known without queueing it for crawling (used for
+	// links that fall outside the crawl's scope - see Crawler.inScope - so
+	// they're remembered but never fetched), and reports whether it was
+	// already known.
+	Seen(url string) bool
+	// Results returns every URL seen so far, mapped to its outcome. URLs
+	// still queued or in progress map to nil.
+	Results() map[string]*QueueResult
+	// Close releases any resources held by the queue.
+	Close() error
+}
+
+// memoryQueue is an in-memory Queue, equivalent to the frontier the
+// crawler used before persistence was introduced.
+type memoryQueue struct {
+	mutex   sync.Mutex
+	pending []QueueItem
+	results map[string]*QueueResult
+}
+
+// NewMemoryQueue creates a Queue that keeps the frontier and results in
+// memory only; nothing survives a crash or restart.
+func NewMemoryQueue() Queue {
+	return &memoryQueue{results: make(map[string]*QueueResult)}
+}
+
+func (q *memoryQueue) Push(url string, depth int, lastMod time.Time) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if _, seen := q.results[url]; seen {
+		return false
+	}
+
+	q.results[url] = nil
+	q.pending = append(q.pending, QueueItem{URL: url, Depth: depth, LastMod: lastMod})
+
+	return true
+}
+
+func (q *memoryQueue) Pop() (QueueItem, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(q.pending) == 0 {
+		return QueueItem{}, false
+	}
+
+	item := q.pending[0]
+	q.pending = q.pending[1:]
+
+	return item, true
+}
+
+func (q *memoryQueue) MarkDone(url string, result QueueResult) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	r := result
+	q.results[url] = &r
+}
+
+func (q *memoryQueue) Seen(url string) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if _, seen := q.results[url]; seen {
+		return true
+	}
+
+	q.results[url] = &QueueResult{}
+
+	return false
+}
+
+func (q *memoryQueue) Results() map[string]*QueueResult {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	out := make(map[string]*QueueResult, len(q.results))
+	for url, result := range q.results {
+		out[url] = result
+	}
+
+	return out
+}
+
+func (q *memoryQueue) Close() error {
+	return nil
+}
@@ -0,0 +1,73 @@
+package crawler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryQueuePushPopMarkDone(t *testing.T) {
+	q := NewMemoryQueue()
+
+	if !q.Push("http://example.com/", 0, time.Time{}) {
+		t.Fatalf("expected first push to succeed")
+	}
+
+	if q.Push("http://example.com/", 0, time.Time{}) {
+		t.Errorf("expected duplicate push to be rejected")
+	}
+
+	item, ok := q.Pop()
+	if !ok {
+		t.Fatalf("expected an item to pop")
+	}
+	if item.URL != "http://example.com/" || item.Depth != 0 {
+		t.Errorf("unexpected item: %+v", item)
+	}
+
+	if _, ok := q.Pop(); ok {
+		t.Errorf("expected frontier to be empty")
+	}
+
+	q.MarkDone(item.URL, QueueResult{Status: "done", ContentType: "text/html"})
+
+	results := q.Results()
+	result, ok := results[item.URL]
+	if !ok || result == nil {
+		t.Fatalf("expected a recorded result for %s", item.URL)
+	}
+	if result.Status != "done" {
+		t.Errorf("expected status 'done', got %q", result.Status)
+	}
+}
+
+func TestMemoryQueuePushRecordsLastMod(t *testing.T) {
+	q := NewMemoryQueue()
+	lastMod := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	q.Push("http://example.com/", 0, lastMod)
+
+	item, ok := q.Pop()
+	if !ok {
+		t.Fatalf("expected an item to pop")
+	}
+	if !item.LastMod.Equal(lastMod) {
+		t.Errorf("expected LastMod %v, got %v", lastMod, item.LastMod)
+	}
+}
+
+func TestMemoryQueueSeen(t *testing.T) {
+	q := NewMemoryQueue()
+
+	if q.Seen("http://example.com/image.jpg") {
+		t.Errorf("expected first Seen call to report not-already-known")
+	}
+
+	if !q.Seen("http://example.com/image.jpg") {
+		t.Errorf("expected second Seen call to report already-known")
+	}
+
+	// A resource only ever Seen should never be handed out by Pop.
+	if _, ok := q.Pop(); ok {
+		t.Errorf("expected Seen-only URLs to stay out of the frontier")
+	}
+}
@@ -0,0 +1,190 @@
+package crawler
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultCrawlDelay is used when robots.txt specifies no Crawl-delay and the
+// caller hasn't configured one with SetCrawlDelay.
+const defaultCrawlDelay = 1 * time.Second
+
+// robotsRules holds the directives that apply to our configured user agent
+// for a single host.
+type robotsRules struct {
+	disallow   []string
+	crawlDelay time.Duration
+	sitemaps   []string // Sitemap: directives; apply site-wide, not per-group
+}
+
+// allows reports whether path is permitted by the parsed rules.
+func (r *robotsRules) allows(path string) bool {
+	if r == nil {
+		return true
+	}
+
+	for _, prefix := range r.disallow {
+		if prefix == "" {
+			continue
+		}
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fetchRobotsRules downloads and parses robots.txt for baseURL's host,
+// scoped to the given userAgent. A missing or unreadable robots.txt is
+// treated as "allow everything".
+func fetchRobotsRules(client *http.Client, baseURL *url.URL, userAgent string) *robotsRules {
+	robotsURL := &url.URL{Scheme: baseURL.Scheme, Host: baseURL.Host, Path: "/robots.txt"}
+
+	resp, err := client.Get(robotsURL.String())
+	if err != nil {
+		return &robotsRules{}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &robotsRules{}
+	}
+
+	return parseRobots(resp.Body, userAgent)
+}
+
+// parseRobots implements enough of the robots.txt exclusion protocol to
+// honor Disallow and Crawl-delay for a single user agent, falling back to
+// the "*" group when there is no group specific to it.
+func parseRobots(body io.Reader, userAgent string) *robotsRules {
+	rules := &robotsRules{}
+
+	var (
+		matchesUs    bool
+		matchesStar  bool
+		inGroup      bool
+		forUs        []string
+		forUsDelay   time.Duration
+		forStar      []string
+		forStarDelay time.Duration
+		sitemaps     []string
+	)
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		field, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		field = strings.ToLower(strings.TrimSpace(field))
+		value = strings.TrimSpace(value)
+
+		switch field {
+		case "user-agent":
+			if inGroup {
+				// A new User-agent line after directives starts a new group.
+				matchesUs, matchesStar = false, false
+				inGroup = false
+			}
+			if value == "*" {
+				matchesStar = true
+			} else if strings.EqualFold(value, userAgent) || strings.Contains(userAgent, value) {
+				matchesUs = true
+			}
+		case "disallow":
+			inGroup = true
+			if matchesUs {
+				forUs = append(forUs, value)
+			} else if matchesStar {
+				forStar = append(forStar, value)
+			}
+		case "crawl-delay":
+			inGroup = true
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+				delay := time.Duration(seconds * float64(time.Second))
+				if matchesUs {
+					forUsDelay = delay
+				} else if matchesStar {
+					forStarDelay = delay
+				}
+			}
+		case "sitemap":
+			// Sitemap applies to the whole file, not to the group it happens
+			// to appear in.
+			if value != "" {
+				sitemaps = append(sitemaps, value)
+			}
+		}
+	}
+
+	if len(forUs) > 0 || forUsDelay > 0 {
+		rules.disallow = forUs
+		rules.crawlDelay = forUsDelay
+	} else {
+		rules.disallow = forStar
+		rules.crawlDelay = forStarDelay
+	}
+
+	rules.sitemaps = sitemaps
+
+	return rules
+}
+
+// robotsRulesFor returns the cached robots.txt rules for host, fetching and
+// caching them on first use.
+func (c *Crawler) robotsRulesFor(baseURL *url.URL) *robotsRules {
+	c.robotsMutex.Lock()
+	if rules, ok := c.robotsCache[baseURL.Host]; ok {
+		c.robotsMutex.Unlock()
+		return rules
+	}
+	c.robotsMutex.Unlock()
+
+	rules := fetchRobotsRules(c.robotsClient(), baseURL, c.userAgent)
+
+	c.robotsMutex.Lock()
+	c.robotsCache[baseURL.Host] = rules
+	c.robotsMutex.Unlock()
+
+	return rules
+}
+
+func (c *Crawler) robotsClient() *http.Client {
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+// isAllowedByRobots reports whether baseURL may be fetched under the
+// configured User-Agent, consulting (and populating) the robots.txt cache.
+func (c *Crawler) isAllowedByRobots(baseURL *url.URL) bool {
+	if !c.respectRobots {
+		return true
+	}
+
+	rules := c.robotsRulesFor(baseURL)
+
+	return rules.allows(baseURL.Path)
+}
+
+// crawlDelayFor returns the delay to enforce between requests to baseURL's
+// host: robots.txt's Crawl-delay if present, otherwise the configured
+// default.
+func (c *Crawler) crawlDelayFor(baseURL *url.URL) time.Duration {
+	if c.respectRobots {
+		if rules := c.robotsRulesFor(baseURL); rules.crawlDelay > 0 {
+			return rules.crawlDelay
+		}
+	}
+
+	return c.crawlDelay
+}
@@ -0,0 +1,68 @@
+package crawler
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRobotsDisallow(t *testing.T) {
+	body := strings.NewReader(`
+User-agent: *
+Disallow: /private
+Crawl-delay: 2
+
+User-agent: GoBot
+Disallow: /bot-only
+`)
+
+	rules := parseRobots(body, "GoBot")
+
+	if rules.allows("/bot-only/page") {
+		t.Errorf("expected /bot-only/page to be disallowed for GoBot")
+	}
+
+	if !rules.allows("/private/page") {
+		t.Errorf("expected /private/page to be allowed for GoBot (only in the * group)")
+	}
+}
+
+func TestParseRobotsFallsBackToWildcard(t *testing.T) {
+	body := strings.NewReader(`
+User-agent: *
+Disallow: /private
+Crawl-delay: 3
+`)
+
+	rules := parseRobots(body, "GoBot")
+
+	if rules.allows("/private/page") {
+		t.Errorf("expected /private/page to be disallowed via the * group")
+	}
+
+	if rules.crawlDelay != 3*time.Second {
+		t.Errorf("expected crawl delay of 3s, got %s", rules.crawlDelay)
+	}
+}
+
+func TestParseRobotsSitemap(t *testing.T) {
+	body := strings.NewReader(`
+User-agent: *
+Disallow: /private
+
+Sitemap: https://example.com/sitemap.xml
+Sitemap: https://example.com/sitemap-news.xml
+`)
+
+	rules := parseRobots(body, "GoBot")
+
+	want := []string{"https://example.com/sitemap.xml", "https://example.com/sitemap-news.xml"}
+	if len(rules.sitemaps) != len(want) {
+		t.Fatalf("expected %d sitemaps, got %v", len(want), rules.sitemaps)
+	}
+	for i, url := range want {
+		if rules.sitemaps[i] != url {
+			t.Errorf("sitemap %d: expected %q, got %q", i, url, rules.sitemaps[i])
+		}
+	}
+}
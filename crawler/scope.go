@@ -0,0 +1,62 @@
+package crawler
+
+// LinkTag categorizes a discovered link by how the page referenced it.
+type LinkTag int
+
+const (
+	// TagPrimary marks navigational links - <a href> and <iframe src> (and
+	// meta refresh) - the edges depth limits are measured across.
+	TagPrimary LinkTag = iota
+	// TagRelated marks embedded resources that make up a complete page
+	// snapshot but aren't navigations: <img>, <link>, <script>, <source>,
+	// CSS url(...), and similar.
+	TagRelated
+)
+
+func (t LinkTag) String() string {
+	switch t {
+	case TagPrimary:
+		return "primary"
+	case TagRelated:
+		return "related"
+	default:
+		return "unknown"
+	}
+}
+
+// ScopeFunc decides whether a discovered link should be enqueued for
+// crawling, given the link itself, how the page referenced it, and the
+// depth it would be crawled at.
+type ScopeFunc func(url string, tag LinkTag, depth int) bool
+
+// inScope reports whether link should be enqueued, consulting the
+// configured ScopeFunc if SetScope was called, or falling back to
+// SetMaxDepth's policy: follow primary links up to maxDepth hops, but
+// always fetch related resources regardless of depth, so a page's
+// snapshot stays complete.
+func (c *Crawler) inScope(link string, tag LinkTag, depth int) bool {
+	if c.scope != nil {
+		return c.scope(link, tag, depth)
+	}
+
+	if tag == TagRelated {
+		return true
+	}
+
+	return c.maxDepth <= 0 || depth <= c.maxDepth
+}
+
+// SetMaxDepth limits how many primary-link hops from the start URL the
+// crawler will follow. Related resources (images, stylesheets, scripts,
+// ...) are always fetched so a page's snapshot remains complete, regardless
+// of depth. Zero (the default) means unlimited. Has no effect once
+// SetScope has been called.
+func (c *Crawler) SetMaxDepth(maxDepth int) {
+	c.maxDepth = maxDepth
+}
+
+// SetScope overrides the default SetMaxDepth policy with a custom one
+// deciding whether a discovered link should be followed.
+func (c *Crawler) SetScope(scope ScopeFunc) {
+	c.scope = scope
+}
@@ -0,0 +1,53 @@
+package crawler
+
+import "testing"
+
+func TestInScopeDefaultMaxDepth(t *testing.T) {
+	c := NewCrawler(5)
+	c.SetMaxDepth(2)
+
+	if !c.inScope("http://example.com/a", TagPrimary, 2) {
+		t.Errorf("expected a primary link at the depth limit to be in scope")
+	}
+
+	if c.inScope("http://example.com/b", TagPrimary, 3) {
+		t.Errorf("expected a primary link past the depth limit to be out of scope")
+	}
+
+	if !c.inScope("http://example.com/img.png", TagRelated, 10) {
+		t.Errorf("expected a related resource to be in scope regardless of depth")
+	}
+}
+
+func TestInScopeCustomScope(t *testing.T) {
+	c := NewCrawler(5)
+	c.SetMaxDepth(2) // should be overridden by SetScope
+
+	c.SetScope(func(url string, tag LinkTag, depth int) bool {
+		return tag == TagPrimary && depth == 0
+	})
+
+	if !c.inScope("http://example.com/a", TagPrimary, 0) {
+		t.Errorf("expected custom scope to allow a depth-0 primary link")
+	}
+
+	if c.inScope("http://example.com/img.png", TagRelated, 0) {
+		t.Errorf("expected custom scope to reject related resources")
+	}
+}
+
+func TestFollowLinkRecordsOutOfScopeLinksAsSeenWithoutQueueing(t *testing.T) {
+	c := NewCrawler(5)
+	c.SetAllowedDomains([]string{"example.com"})
+	c.SetMaxDepth(1)
+
+	c.followLink("http://example.com/too-deep", TagPrimary, 2)
+
+	if _, ok := c.queue.Pop(); ok {
+		t.Errorf("expected out-of-scope link not to be queued for crawling")
+	}
+
+	if !c.queue.Seen("http://example.com/too-deep") {
+		t.Errorf("expected out-of-scope link to already be recorded as seen")
+	}
+}
@@ -0,0 +1,64 @@
+package crawler
+
+import (
+	"net/http"
+	"time"
+)
+
+// ResponseMeta carries metadata about a single fetched URL, alongside its
+// headers and body, for Sink.OnResponse.
+type ResponseMeta struct {
+	StatusCode  int
+	ContentType string
+	Redirect    string
+	Depth       int
+	Duration    time.Duration
+}
+
+// Sink receives crawl events as they happen, so callers can stream
+// results into storage, logging, or another pipeline without waiting for
+// the crawl to finish. Implementations must be safe for concurrent use,
+// since the crawler's worker pool calls them from multiple goroutines.
+type Sink interface {
+	// OnResponse is called once per successfully fetched URL, after every
+	// link it references has already been reported via OnLink.
+	OnResponse(url string, headers http.Header, body []byte, meta ResponseMeta)
+	// OnError is called when fetching url failed.
+	OnError(url string, err error)
+	// OnLink is called for every link discovered on from, before it's
+	// filtered by domain policy or scope.
+	OnLink(from, to string, tag LinkTag)
+}
+
+// AddSink registers a sink to receive crawl events, alongside any already
+// registered. Sinks are invoked synchronously, in registration order, as
+// each event happens.
+func (c *Crawler) AddSink(sink Sink) {
+	c.sinks = append(c.sinks, sink)
+}
+
+func (c *Crawler) notifyResponse(url string, result fetchResult, redirect string, depth int, duration time.Duration) {
+	meta := ResponseMeta{
+		StatusCode:  result.statusCode,
+		ContentType: result.headers.Get("Content-Type"),
+		Redirect:    redirect,
+		Depth:       depth,
+		Duration:    duration,
+	}
+
+	for _, sink := range c.sinks {
+		sink.OnResponse(url, result.headers, []byte(result.body), meta)
+	}
+}
+
+func (c *Crawler) notifyError(url string, err error) {
+	for _, sink := range c.sinks {
+		sink.OnError(url, err)
+	}
+}
+
+func (c *Crawler) notifyLink(from, to string, tag LinkTag) {
+	for _, sink := range c.sinks {
+		sink.OnLink(from, to, tag)
+	}
+}
@@ -0,0 +1,55 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/ciprian-test/web-crawler/sitemap"
+)
+
+// SeedFromSitemap fetches sitemapURL - a sitemap or sitemap index,
+// optionally gzipped - and enqueues every URL it names within the allowed
+// domains at depth 0, recording each entry's lastmod for use with
+// SetIfModifiedSince.
+func (c *Crawler) SeedFromSitemap(sitemapURL string) error {
+	entries, err := sitemap.Fetch(c.robotsClient(), sitemapURL)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !c.isDomainAllowedForLink(entry.Loc) {
+			continue
+		}
+
+		c.enqueueWithLastMod(entry.Loc, 0, entry.LastMod)
+	}
+
+	return nil
+}
+
+// SetIfModifiedSince enables conditional GETs for URLs seeded with a known
+// lastmod (see SeedFromSitemap): an If-Modified-Since header is sent, and a
+// 304 response is recorded without being parsed for further links.
+func (c *Crawler) SetIfModifiedSince(enabled bool) {
+	c.ifModifiedSince = enabled
+}
+
+// discoverSitemaps seeds every sitemap advertised by startURL's host's
+// robots.txt, when SetRespectRobots is enabled.
+func (c *Crawler) discoverSitemaps(startURL string) {
+	if !c.respectRobots {
+		return
+	}
+
+	baseURL, err := url.Parse(startURL)
+	if err != nil {
+		return
+	}
+
+	for _, sitemapURL := range c.robotsRulesFor(baseURL).sitemaps {
+		if err := c.SeedFromSitemap(sitemapURL); err != nil {
+			fmt.Println("Error seeding sitemap", sitemapURL, ":", err)
+		}
+	}
+}
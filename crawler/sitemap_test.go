@@ -0,0 +1,110 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestSeedFromSitemapEnqueuesLocEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>` + "http://example.invalid/page1" + `</loc></url>
+</urlset>`))
+	}))
+	defer server.Close()
+
+	c := NewCrawler(1)
+	c.SetAllowedDomains([]string{"example.invalid"})
+
+	if err := c.SeedFromSitemap(server.URL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results := c.queue.Results()
+	if _, ok := results["http://example.invalid/page1"]; !ok {
+		t.Errorf("expected sitemap entry to be enqueued, got %v", results)
+	}
+}
+
+func TestCrawlDiscoversSitemapFromRobots(t *testing.T) {
+	handler := http.NewServeMux()
+
+	handler.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("User-agent: *\nSitemap: " + sitemapURLPlaceholder + "\n"))
+	})
+	handler.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html></html>"))
+	})
+
+	var server *httptest.Server
+	handler.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>` + server.URL + `/from-sitemap</loc></url>
+</urlset>`))
+	})
+	handler.HandleFunc("/from-sitemap", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html></html>"))
+	})
+
+	server = httptest.NewServer(handler)
+	defer server.Close()
+	sitemapURLPlaceholder = server.URL + "/sitemap.xml"
+
+	host := mustHost(server.URL)
+
+	c := NewCrawler(2)
+	c.SetAllowedDomains([]string{host})
+	c.SetRespectRobots(true)
+
+	c.Crawl(server.URL + "/")
+
+	results := c.queue.Results()
+	if _, ok := results[server.URL+"/from-sitemap"]; !ok {
+		t.Errorf("expected URL discovered via robots.txt sitemap to be crawled, got %v", results)
+	}
+}
+
+func TestIfModifiedSinceSkipsUnchangedResponses(t *testing.T) {
+	var gotHeader string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("If-Modified-Since")
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	c := NewCrawler(1)
+	c.SetAllowedDomains([]string{mustHost(server.URL)})
+	c.SetIfModifiedSince(true)
+
+	lastMod := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	c.enqueueWithLastMod(server.URL+"/", 0, lastMod)
+	c.runUntilDone()
+
+	if gotHeader == "" {
+		t.Fatalf("expected an If-Modified-Since header to be sent")
+	}
+
+	result := c.queue.Results()[server.URL+"/"]
+	if result == nil || result.Status != "not-modified" {
+		t.Errorf("expected status 'not-modified', got %+v", result)
+	}
+}
+
+// sitemapURLPlaceholder lets the /robots.txt handler above reference the
+// sitemap URL before the server (and thus its own URL) exists.
+var sitemapURLPlaceholder string
+
+func mustHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Host
+}
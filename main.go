@@ -12,13 +12,13 @@ func main() {
 	startURL := os.Getenv("START_URL")
 	allowedDomains := os.Getenv("ALLOWED_DOMAINS")
 
-	crawler := crawler.NewCrawler(5)
+	c := crawler.NewCrawler(5)
+	c.AddSink(crawler.NewJSONLSink(os.Stdout))
 
 	if len(allowedDomains) > 0 {
-		crawler.SetAllowedDomains(strings.Split(allowedDomains, ","))
+		c.SetAllowedDomains(strings.Split(allowedDomains, ","))
 	}
 
-	crawler.Crawl(startURL)
-
-	crawler.PrintLinks(false)
+	c.Crawl(startURL)
+	c.Close()
 }
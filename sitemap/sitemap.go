@@ -0,0 +1,143 @@
+// Package sitemap fetches and parses sitemap.xml files: plain urlsets,
+// sitemap indexes (followed recursively), and their gzipped variants.
+package sitemap
+
+import (
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Entry is a single <url> named by a sitemap, with its optional
+// last-modified time.
+type Entry struct {
+	Loc     string
+	LastMod time.Time // zero if absent or unparseable
+}
+
+// maxIndexDepth guards against a misconfigured or cyclic sitemap index.
+const maxIndexDepth = 5
+
+// Fetch retrieves and parses the sitemap (or sitemap index) at sitemapURL,
+// recursively following any nested sitemaps named by an index, and returns
+// every <url> entry it names. A response whose URL ends in ".gz" or whose
+// Content-Encoding is "gzip" is decompressed transparently.
+func Fetch(client *http.Client, sitemapURL string) ([]Entry, error) {
+	return fetch(client, sitemapURL, 0)
+}
+
+func fetch(client *http.Client, sitemapURL string, depth int) ([]Entry, error) {
+	if depth > maxIndexDepth {
+		return nil, fmt.Errorf("sitemap index nested too deeply at %s", sitemapURL)
+	}
+
+	data, err := get(client, sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if entries, err := parseURLSet(data); err == nil {
+		return entries, nil
+	}
+
+	locs, err := parseSitemapIndex(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither a urlset nor a sitemapindex", sitemapURL)
+	}
+
+	var entries []Entry
+	for _, loc := range locs {
+		nested, err := fetch(client, loc, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, nested...)
+	}
+
+	return entries, nil
+}
+
+// get downloads sitemapURL, decompressing it if it's gzipped.
+func get(client *http.Client, sitemapURL string) ([]byte, error) {
+	resp, err := client.Get(sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %d status code", sitemapURL, resp.StatusCode)
+	}
+
+	var reader io.Reader = resp.Body
+	if strings.HasSuffix(sitemapURL, ".gz") || resp.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	return io.ReadAll(reader)
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	URLs    []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+func parseURLSet(data []byte) ([]Entry, error) {
+	var doc urlSet
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(doc.URLs))
+	for _, u := range doc.URLs {
+		entries = append(entries, Entry{Loc: u.Loc, LastMod: parseLastMod(u.LastMod)})
+	}
+
+	return entries, nil
+}
+
+func parseSitemapIndex(data []byte) ([]string, error) {
+	var doc sitemapIndex
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	locs := make([]string, 0, len(doc.Sitemaps))
+	for _, s := range doc.Sitemaps {
+		locs = append(locs, s.Loc)
+	}
+
+	return locs, nil
+}
+
+// parseLastMod parses a <lastmod> value, which sitemaps may give as either a
+// full timestamp or a bare date. The zero Time is returned if value is empty
+// or in neither format.
+func parseLastMod(value string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
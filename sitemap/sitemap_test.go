@@ -0,0 +1,88 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetchParsesURLSet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>http://example.com/page1</loc><lastmod>2024-01-15</lastmod></url>
+  <url><loc>http://example.com/page2</loc></url>
+</urlset>`))
+	}))
+	defer server.Close()
+
+	entries, err := Fetch(server.Client(), server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Loc != "http://example.com/page1" {
+		t.Errorf("unexpected loc: %s", entries[0].Loc)
+	}
+	want := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	if !entries[0].LastMod.Equal(want) {
+		t.Errorf("expected lastmod %v, got %v", want, entries[0].LastMod)
+	}
+	if !entries[1].LastMod.IsZero() {
+		t.Errorf("expected no lastmod for page2, got %v", entries[1].LastMod)
+	}
+}
+
+func TestFetchFollowsSitemapIndex(t *testing.T) {
+	var nestedURL string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>` + nestedURL + `</loc></sitemap>
+</sitemapindex>`))
+	})
+	mux.HandleFunc("/nested.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>http://example.com/nested-page</loc></url>
+</urlset>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	nestedURL = server.URL + "/nested.xml"
+
+	entries, err := Fetch(server.Client(), server.URL+"/sitemap.xml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Loc != "http://example.com/nested-page" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestFetchDecompressesGzip(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>http://example.com/gz-page</loc></url>
+</urlset>`))
+	gz.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	entries, err := Fetch(server.Client(), server.URL+"/sitemap.xml.gz")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Loc != "http://example.com/gz-page" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
@@ -0,0 +1,51 @@
+// Package warc writes crawl responses as WARC 1.0 records, suitable for
+// feeding into replay systems such as pywb.
+package warc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+// newRecordID returns an RFC-3987 URN-formatted WARC-Record-ID, unique
+// enough to link a request record to its matching response record via
+// WARC-Concurrent-To.
+func newRecordID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// warcDate formats t the way the WARC-Date field requires.
+func warcDate(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+// buildRecord renders a single WARC 1.0 record: its header block, a blank
+// line, the content block, and the two trailing newlines that separate
+// records in a WARC file.
+func buildRecord(recordType, recordID, concurrentTo, targetURI, date, contentType string, content []byte) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprint(&buf, "WARC/1.0\r\n")
+	fmt.Fprintf(&buf, "WARC-Type: %s\r\n", recordType)
+	fmt.Fprintf(&buf, "WARC-Record-ID: %s\r\n", recordID)
+	fmt.Fprintf(&buf, "WARC-Target-URI: %s\r\n", targetURI)
+	fmt.Fprintf(&buf, "WARC-Date: %s\r\n", date)
+	if concurrentTo != "" {
+		fmt.Fprintf(&buf, "WARC-Concurrent-To: %s\r\n", concurrentTo)
+	}
+	fmt.Fprintf(&buf, "Content-Type: %s\r\n", contentType)
+	fmt.Fprintf(&buf, "Content-Length: %d\r\n", len(content))
+	buf.WriteString("\r\n")
+	buf.Write(content)
+	buf.WriteString("\r\n\r\n")
+
+	return buf.Bytes()
+}
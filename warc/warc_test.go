@@ -0,0 +1,74 @@
+package warc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteResponseProducesMatchingRecordPair(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.warc")
+
+	w, err := NewWriter(path, Options{})
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+
+	requestHead := []byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n")
+	responseRaw := []byte("HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n\r\n<html></html>")
+
+	if err := w.WriteResponse("http://example.com/", requestHead, responseRaw); err != nil {
+		t.Fatalf("WriteResponse failed: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading WARC file failed: %v", err)
+	}
+
+	content := string(data)
+
+	if !strings.Contains(content, "WARC-Type: response") {
+		t.Errorf("expected a response record, got: %s", content)
+	}
+	if !strings.Contains(content, "WARC-Type: request") {
+		t.Errorf("expected a request record, got: %s", content)
+	}
+	if !strings.Contains(content, "WARC-Target-URI: http://example.com/") {
+		t.Errorf("expected WARC-Target-URI, got: %s", content)
+	}
+	if !strings.Contains(content, "Content-Type: application/http; msgtype=response") {
+		t.Errorf("expected response Content-Type, got: %s", content)
+	}
+	if !bytes.Contains(data, responseRaw) {
+		t.Errorf("expected the raw response bytes to be embedded")
+	}
+}
+
+func TestWriterRotatesOnMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.warc")
+
+	w, err := NewWriter(path, Options{MaxBytes: 1})
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.WriteResponse("http://example.com/a", []byte("req-a"), []byte("resp-a")); err != nil {
+		t.Fatalf("WriteResponse failed: %v", err)
+	}
+	if err := w.WriteResponse("http://example.com/b", []byte("req-b"), []byte("resp-b")); err != nil {
+		t.Fatalf("WriteResponse failed: %v", err)
+	}
+
+	rotated := filepath.Join(filepath.Dir(path), "crawl-000001.warc")
+	if _, err := os.Stat(rotated); err != nil {
+		t.Errorf("expected rotated file %s to exist: %v", rotated, err)
+	}
+}